@@ -8,30 +8,145 @@ package ethereum
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
 	"math/big"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/params"
-	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/omgnetwork/immutability-eth-plugin/contracts/ovm_ctc"
 	"github.com/omgnetwork/immutability-eth-plugin/contracts/ovm_scc"
+	"github.com/omgnetwork/immutability-eth-plugin/noncemgr"
+	"github.com/omgnetwork/immutability-eth-plugin/ovm/batchcodec"
 	"github.com/omgnetwork/immutability-eth-plugin/util"
 )
 
 const ovm string = "ovm"
 
+const (
+	// defaultRPCTimeout bounds how long dialing an upstream RPC endpoint may
+	// take, and how long clearPendingTransactions' upstream calls may take
+	// when the caller doesn't supply an rpc_timeout.
+	defaultRPCTimeout = 30 * time.Second
+	// defaultRPCMaxBatchSize caps how many calls nonceWindow folds into a
+	// single rpc.BatchCallContext round trip when the caller doesn't supply
+	// an rpc_max_batch_size.
+	defaultRPCMaxBatchSize = 20
+)
+
+// parseRPCTimeout reads the rpc_timeout field (in seconds) off data,
+// defaulting to defaultRPCTimeout when omitted.
+func parseRPCTimeout(data *framework.FieldData) (time.Duration, error) {
+	v := data.Get("rpc_timeout").(string)
+	if v == "" {
+		return defaultRPCTimeout, nil
+	}
+	seconds, err := strconv.Atoi(v)
+	if err != nil || seconds <= 0 {
+		return 0, fmt.Errorf("invalid rpc_timeout")
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// parseRPCMaxBatchSize reads the rpc_max_batch_size field off data,
+// defaulting to defaultRPCMaxBatchSize when omitted.
+func parseRPCMaxBatchSize(data *framework.FieldData) (int, error) {
+	v := data.Get("rpc_max_batch_size").(string)
+	if v == "" {
+		return defaultRPCMaxBatchSize, nil
+	}
+	size, err := strconv.Atoi(v)
+	if err != nil || size <= 0 {
+		return 0, fmt.Errorf("invalid rpc_max_batch_size")
+	}
+	return size, nil
+}
+
+// rpcClientPool caches one *rpc.Client per RPC URL so repeated requests
+// against the same node reuse its underlying HTTP(S) connection (and any TLS
+// session/auth it negotiated) instead of dialing fresh on every handler
+// invocation.
+//
+// This is a package-level sync.Map rather than a field on PluginBackend.
+// Vault's plugin catalog execs one process per enabled backend, so in
+// practice there is exactly one PluginBackend per process and the two scopes
+// coincide; PluginBackend itself is defined outside this package's files, so
+// adding a field here would mean introducing that struct's definition rather
+// than extending it. If this plugin ever hosts multiple PluginBackend
+// instances in one process, this pool should move onto the struct so each
+// instance's connections are scoped and closeable independently.
+var rpcClientPool sync.Map // map[string]*rpc.Client
+
+// dialPooled returns the cached *rpc.Client for url, dialing and caching one
+// if this is the first call to see it.
+func dialPooled(ctx context.Context, url string) (*rpc.Client, error) {
+	if cached, ok := rpcClientPool.Load(url); ok {
+		return cached.(*rpc.Client), nil
+	}
+	dialCtx, cancel := context.WithTimeout(ctx, defaultRPCTimeout)
+	defer cancel()
+	dialed, err := rpc.DialContext(dialCtx, url)
+	if err != nil {
+		return nil, err
+	}
+	actual, loaded := rpcClientPool.LoadOrStore(url, dialed)
+	if loaded {
+		// Another request won the race and cached its own client first.
+		dialed.Close()
+	}
+	return actual.(*rpc.Client), nil
+}
+
+// dialPooledEthClient is dialPooled wrapped in an *ethclient.Client, for call
+// sites that want the typed client rather than raw RPC access.
+func dialPooledEthClient(ctx context.Context, url string) (*ethclient.Client, error) {
+	rpcClient, err := dialPooled(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(rpcClient), nil
+}
+
+// nonceWindow fetches address's latest (mined) and pending transaction
+// counts in a single JSON-RPC batch round trip, instead of the two
+// sequential calls client.NonceAt/client.PendingNonceAt would make. It
+// refuses to run if that batch would exceed maxBatchSize.
+func nonceWindow(ctx context.Context, rpcClient *rpc.Client, address common.Address, maxBatchSize int) (latest, pending uint64, err error) {
+	var latestHex, pendingHex hexutil.Uint64
+	batch := []rpc.BatchElem{
+		{Method: "eth_getTransactionCount", Args: []interface{}{address, "latest"}, Result: &latestHex},
+		{Method: "eth_getTransactionCount", Args: []interface{}{address, "pending"}, Result: &pendingHex},
+	}
+	if len(batch) > maxBatchSize {
+		return 0, 0, fmt.Errorf("nonce lookup batch of %d calls exceeds rpc_max_batch_size %d", len(batch), maxBatchSize)
+	}
+	if err := rpcClient.BatchCallContext(ctx, batch); err != nil {
+		return 0, 0, err
+	}
+	for _, elem := range batch {
+		if elem.Error != nil {
+			return 0, 0, elem.Error
+		}
+	}
+	return uint64(latestHex), uint64(pendingHex), nil
+}
+
 type Context struct {
 	NumSequencedTransactions       int64 `json:"num_sequenced_transactions"`
 	NumSubsequentQueueTransactions int64 `json:"num_subsequent_queue_transactions"`
@@ -68,6 +183,21 @@ func OvmPaths(b *PluginBackend) []*framework.Path {
 			},
 			ExistenceCheck: pathExistenceCheck,
 		},
+		{
+			Pattern: QualifiedPath("decodeAppendSequencerBatch/?"),
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.CreateOperation: b.pathDecodeAppendSequencerBatch,
+			},
+			HelpSynopsis:    "Decoding for AppendSequencerBatch",
+			HelpDescription: `Use this to decode raw AppendSequencerBatch calldata back into its structured fields.`,
+			Fields: map[string]*framework.FieldSchema{
+				"data": {
+					Type:        framework.TypeString,
+					Description: "Raw AppendSequencerBatch calldata (without the function selector) to decode.",
+				},
+			},
+			ExistenceCheck: pathExistenceCheck,
+		},
 		{
 			Pattern:         ContractPath(ovm, "appendStateBatch"),
 			HelpSynopsis:    "Submits the state batch",
@@ -81,7 +211,19 @@ func OvmPaths(b *PluginBackend) []*framework.Path {
 				},
 				"gas_price": {
 					Type:        framework.TypeString,
-					Description: "The gas price for the transaction in wei.",
+					Description: "The legacy gas price for the transaction in wei. Ignored when max_fee_per_gas is set.",
+				},
+				"max_fee_per_gas": {
+					Type:        framework.TypeString,
+					Description: "EIP-1559 max fee per gas in wei. When set, produces a dynamic fee transaction instead of a legacy one.",
+				},
+				"max_priority_fee_per_gas": {
+					Type:        framework.TypeString,
+					Description: "EIP-1559 max priority fee (tip) per gas in wei. Required with max_fee_per_gas unless gas_tip_cap_suggest is set.",
+				},
+				"gas_tip_cap_suggest": {
+					Type:        framework.TypeBool,
+					Description: "When true and max_priority_fee_per_gas is omitted, auto-fill the tip from the RPC's suggested gas tip cap.",
 				},
 				"nonce": {
 					Type:        framework.TypeString,
@@ -95,6 +237,10 @@ func OvmPaths(b *PluginBackend) []*framework.Path {
 					Type:        framework.TypeStringSlice,
 					Description: "Batch of state roots.",
 				},
+				"simulate": {
+					Type:        framework.TypeBool,
+					Description: "When true, dry-run the transaction with eth_call and verify the SCC's getTotalElements() matches should_start_at_element before submitting.",
+				},
 			},
 			ExistenceCheck: pathExistenceCheck,
 			Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -114,7 +260,19 @@ func OvmPaths(b *PluginBackend) []*framework.Path {
 				},
 				"gas_price": {
 					Type:        framework.TypeString,
-					Description: "The gas price for the transaction in wei.",
+					Description: "The legacy gas price for the transaction in wei. Ignored when max_fee_per_gas is set.",
+				},
+				"max_fee_per_gas": {
+					Type:        framework.TypeString,
+					Description: "EIP-1559 max fee per gas in wei. When set, produces a dynamic fee transaction instead of a legacy one.",
+				},
+				"max_priority_fee_per_gas": {
+					Type:        framework.TypeString,
+					Description: "EIP-1559 max priority fee (tip) per gas in wei. Required with max_fee_per_gas unless gas_tip_cap_suggest is set.",
+				},
+				"gas_tip_cap_suggest": {
+					Type:        framework.TypeBool,
+					Description: "When true and max_priority_fee_per_gas is omitted, auto-fill the tip from the RPC's suggested gas tip cap.",
 				},
 				"nonce": {
 					Type:        framework.TypeString,
@@ -137,6 +295,10 @@ func OvmPaths(b *PluginBackend) []*framework.Path {
 					Type:        framework.TypeStringSlice,
 					Description: "Transaction data.",
 				},
+				"simulate": {
+					Type:        framework.TypeBool,
+					Description: "When true, dry-run the transaction with eth_call, verify the CTC's getTotalElements() matches should_start_at_element and the context counts sum to total_elements_to_append before submitting. getNextQueueIndex() is only reported, not checked.",
+				},
 			},
 			ExistenceCheck: pathExistenceCheck,
 			Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -150,12 +312,85 @@ func OvmPaths(b *PluginBackend) []*framework.Path {
 			Fields: map[string]*framework.FieldSchema{
 				"name":    {Type: framework.TypeString, Description: "Name of the wallet."},
 				"address": {Type: framework.TypeString, Description: "The address in the wallet."},
+				"min_bump_percent": {
+					Type:        framework.TypeString,
+					Description: "Minimum percent to bump each replacement's gas price (or tip/fee cap) by. Defaults to 10.",
+				},
+				"max_bump_percent": {
+					Type:        framework.TypeString,
+					Description: "Maximum percent the bump is allowed to escalate to on replacement-underpriced retries. Defaults to 200.",
+				},
+				"max_fee_cap": {
+					Type:        framework.TypeString,
+					Description: "Optional ceiling in wei on the bumped gas price/fee cap; replacement is refused if it would be exceeded.",
+				},
+				"rpc_timeout": {
+					Type:        framework.TypeString,
+					Description: "Timeout in seconds for upstream JSON-RPC calls made while clearing pending transactions. Defaults to 30.",
+				},
+				"rpc_max_batch_size": {
+					Type:        framework.TypeString,
+					Description: "Maximum number of calls folded into a single JSON-RPC batch round trip. Defaults to 20.",
+				},
 			},
 			ExistenceCheck: pathExistenceCheck,
 			Callbacks: map[logical.Operation]framework.OperationFunc{
 				logical.CreateOperation: b.pathOvmClearPendingTransactions,
 			},
 		},
+		{
+			Pattern:         ContractPath(ovm, "nonce/next"),
+			HelpSynopsis:    "Previews the next nonce noncemgr would reserve",
+			HelpDescription: "Returns max(on-chain pending nonce, highest unreleased reservation + 1) without reserving it.",
+			Fields: map[string]*framework.FieldSchema{
+				"name":    {Type: framework.TypeString, Description: "Name of the wallet."},
+				"address": {Type: framework.TypeString, Description: "The address in the wallet."},
+			},
+			ExistenceCheck: pathExistenceCheck,
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.CreateOperation: b.pathOvmNonceNext,
+			},
+		},
+		{
+			Pattern:         ContractPath(ovm, "nonce/reserve"),
+			HelpSynopsis:    "Atomically reserves the next nonce",
+			HelpDescription: "Reserves max(on-chain pending nonce, highest unreleased reservation + 1) and persists it as reserved.",
+			Fields: map[string]*framework.FieldSchema{
+				"name":    {Type: framework.TypeString, Description: "Name of the wallet."},
+				"address": {Type: framework.TypeString, Description: "The address in the wallet."},
+			},
+			ExistenceCheck: pathExistenceCheck,
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.CreateOperation: b.pathOvmNonceReserve,
+			},
+		},
+		{
+			Pattern:         ContractPath(ovm, "nonce/release"),
+			HelpSynopsis:    "Releases a reserved nonce",
+			HelpDescription: "Marks a reservation dropped, e.g. after a signing failure. The nonce is handed out again only while no higher nonce is still reserved, submitted or replaced.",
+			Fields: map[string]*framework.FieldSchema{
+				"name":    {Type: framework.TypeString, Description: "Name of the wallet."},
+				"address": {Type: framework.TypeString, Description: "The address in the wallet."},
+				"nonce":   {Type: framework.TypeString, Description: "The nonce to release."},
+			},
+			ExistenceCheck: pathExistenceCheck,
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.CreateOperation: b.pathOvmNonceRelease,
+			},
+		},
+		{
+			Pattern:         ContractPath(ovm, "nonce/inflight"),
+			HelpSynopsis:    "Lists reservations still reserved or submitted",
+			HelpDescription: "Reconciles reservations against on-chain receipts, then returns every one still reserved or submitted, sorted by nonce. Mined, dropped and replaced reservations are left out.",
+			Fields: map[string]*framework.FieldSchema{
+				"name":    {Type: framework.TypeString, Description: "Name of the wallet."},
+				"address": {Type: framework.TypeString, Description: "The address in the wallet."},
+			},
+			ExistenceCheck: pathExistenceCheck,
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.CreateOperation: b.pathOvmNonceInflight,
+			},
+		},
 	}
 }
 
@@ -177,7 +412,7 @@ func (b *PluginBackend) pathOvmAppendStateBatch(ctx context.Context, req *logica
 		return nil, fmt.Errorf("invalid chain ID")
 	}
 
-	client, err := ethclient.Dial(config.getRPCURL())
+	client, err := dialPooledEthClient(ctx, config.getRPCURL())
 	if err != nil {
 		return nil, err
 	}
@@ -227,19 +462,41 @@ func (b *PluginBackend) pathOvmAppendStateBatch(ctx context.Context, req *logica
 	if err != nil {
 		return nil, err
 	}
-	// transactOpts needs gas etc. Use supplied gas_price
-	gasPriceRaw := data.Get("gas_price").(string)
-	if gasPriceRaw == "" {
-		return nil, fmt.Errorf("invalid gas_price")
+	// transactOpts needs gas pricing. Use supplied gas_price, or max_fee_per_gas/
+	// max_priority_fee_per_gas for an EIP-1559 dynamic fee transaction.
+	pricing, err := b.resolveFeePricing(ctx, client, data)
+	if err != nil {
+		return nil, err
+	}
+	if pricing.Dynamic {
+		transactOpts.GasFeeCap = pricing.GasFeeCap
+		transactOpts.GasTipCap = pricing.GasTipCap
+	} else {
+		transactOpts.GasPrice = pricing.GasPrice
 	}
-	transactOpts.GasPrice = util.ValidNumber(gasPriceRaw)
 
-	// //transactOpts needs nonce. Use supplied nonce
+	// transactOpts needs a nonce. Use the supplied one, or atomically reserve
+	// the next one via noncemgr so concurrent submissions don't collide.
 	nonceRaw := data.Get("nonce").(string)
+	var reservedNonce uint64
+	var haveReservation bool
 	if nonceRaw == "" {
-		return nil, fmt.Errorf("invalid nonce")
+		onChainNonce, err := client.PendingNonceAt(ctx, account.Address)
+		if err != nil {
+			return nil, err
+		}
+		reservedNonce, err = noncemgr.Reserve(ctx, req.Storage, chainID, account.Address.Hex(), onChainNonce)
+		if err != nil {
+			return nil, err
+		}
+		haveReservation = true
+		transactOpts.Nonce = new(big.Int).SetUint64(reservedNonce)
+	} else {
+		transactOpts.Nonce = util.ValidNumber(nonceRaw)
 	}
-	transactOpts.Nonce = util.ValidNumber(nonceRaw)
+	// Sign but don't send yet, so a simulate=true request can dry-run the tx
+	// before it's broadcast.
+	transactOpts.NoSend = true
 
 	sccSession := &ovm_scc.OvmSccSession{
 		Contract:     instance,  // Generic contract caller binding to set the session for
@@ -249,37 +506,197 @@ func (b *PluginBackend) pathOvmAppendStateBatch(ctx context.Context, req *logica
 
 	tx, err := sccSession.AppendStateBatch(batch, shouldStartAtElement)
 	if err != nil {
+		if haveReservation {
+			releaseNonceReservation(ctx, req.Storage, chainID, account.Address.Hex(), reservedNonce)
+		}
 		return nil, err
 	}
 
+	var invariants map[string]interface{}
+	if data.Get("simulate").(bool) {
+		invariants, err = b.simulateAppendStateBatch(ctx, client, instance, callOpts, contractAddress, account.Address, tx, shouldStartAtElement)
+		if err != nil {
+			if haveReservation {
+				releaseNonceReservation(ctx, req.Storage, chainID, account.Address.Hex(), reservedNonce)
+			}
+			return nil, err
+		}
+	}
+
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		if haveReservation {
+			releaseNonceReservation(ctx, req.Storage, chainID, account.Address.Hex(), reservedNonce)
+		}
+		return nil, err
+	}
+
+	// Only record the reservation as submitted once the transaction has
+	// actually been broadcast; recording it any earlier would leave a
+	// reservation permanently marked submitted for a tx that was never sent.
+	if haveReservation {
+		if err := noncemgr.Record(ctx, req.Storage, chainID, account.Address.Hex(), reservedNonce, tx.Hash().Hex(), pricing.recordedGasPrice()); err != nil {
+			return nil, err
+		}
+	}
+
 	var signedTxBuff bytes.Buffer
 	tx.EncodeRLP(&signedTxBuff)
+	responseData := feeResponseData(pricing, map[string]interface{}{
+		"contract":           contractAddress.Hex(),
+		"transaction_hash":   tx.Hash().Hex(),
+		"signed_transaction": hexutil.Encode(signedTxBuff.Bytes()),
+		"from":               account.Address.Hex(),
+		"nonce":              tx.Nonce(),
+		"gas_limit":          tx.Gas(),
+	})
+	for k, v := range invariants {
+		responseData[k] = v
+	}
 	return &logical.Response{
-		Data: map[string]interface{}{
-			"contract":           contractAddress.Hex(),
-			"transaction_hash":   tx.Hash().Hex(),
-			"signed_transaction": hexutil.Encode(signedTxBuff.Bytes()),
-			"from":               account.Address.Hex(),
-			"nonce":              tx.Nonce(),
-			"gas_price":          tx.GasPrice(),
-			"gas_limit":          tx.Gas(),
-		},
+		Data: responseData,
+	}, nil
+}
+
+// simulateAppendStateBatch dry-runs tx against the SCC via eth_call and
+// verifies should_start_at_element still matches the contract's current
+// getTotalElements(), returning the computed invariants for the caller to
+// diff against their expectations.
+func (b *PluginBackend) simulateAppendStateBatch(ctx context.Context, client *ethclient.Client, instance *ovm_scc.OvmScc, callOpts *bind.CallOpts, contractAddress, from common.Address, tx *types.Transaction, shouldStartAtElement *big.Int) (map[string]interface{}, error) {
+	totalElements, err := instance.GetTotalElements(callOpts)
+	if err != nil {
+		return nil, fmt.Errorf("fetching SCC getTotalElements: %w", err)
+	}
+	if totalElements.Cmp(shouldStartAtElement) != 0 {
+		return nil, fmt.Errorf("should_start_at_element %s does not match on-chain getTotalElements() %s, refusing to submit", shouldStartAtElement, totalElements)
+	}
+
+	if err := callContractAndDecodeRevert(ctx, client, from, contractAddress, tx.Data()); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"simulated":               true,
+		"total_elements":          totalElements.String(),
+		"should_start_at_element": shouldStartAtElement.String(),
 	}, nil
 }
 
 func (b *PluginBackend) pathEncodeAppendSequencerBatch(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 
-	encodedData, err := encode(data)
+	params, err := paramsFromData(data)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := batchcodec.Encode(params)
 	if err != nil {
 		return nil, err
 	}
 	return &logical.Response{
 		Data: map[string]interface{}{
-			"data": encodedData,
+			"data": hex.EncodeToString(encoded),
 		},
 	}, nil
 }
 
+func (b *PluginBackend) pathDecodeAppendSequencerBatch(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	inputData, ok := data.GetOk("data")
+	if !ok {
+		return nil, fmt.Errorf("invalid data")
+	}
+	params, err := batchcodec.Decode(common.FromHex(inputData.(string)))
+	if err != nil {
+		return nil, err
+	}
+
+	contexts := make([]map[string]interface{}, len(params.Contexts))
+	for i, c := range params.Contexts {
+		contexts[i] = map[string]interface{}{
+			"num_sequenced_transactions":        c.NumSequencedTransactions,
+			"num_subsequent_queue_transactions": c.NumSubsequentQueueTransactions,
+			"timestamp":                         c.Timestamp,
+			"block_number":                      c.BlockNumber,
+		}
+	}
+	transactions := make([]string, len(params.Transactions))
+	for i, tx := range params.Transactions {
+		transactions[i] = hexutil.Encode(tx)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"should_start_at_element":  params.ShouldStartAtElement,
+			"total_elements_to_append": params.TotalElementsToAppend,
+			"contexts":                 contexts,
+			"transactions":             transactions,
+		},
+	}, nil
+}
+
+// paramsFromData parses the should_start_at_element, total_elements_to_append,
+// contexts and transactions fields off data into an AppendSequencerBatchParams
+// ready for batchcodec.Encode.
+func paramsFromData(data *framework.FieldData) (batchcodec.AppendSequencerBatchParams, error) {
+	var params batchcodec.AppendSequencerBatchParams
+
+	shouldStartAtElementRaw := data.Get("should_start_at_element").(string)
+	if util.ValidNumber(shouldStartAtElementRaw) == nil {
+		return params, fmt.Errorf("invalid should_start_at_element")
+	}
+	shouldStartAtElement, err := strconv.ParseUint(shouldStartAtElementRaw, 10, 64)
+	if err != nil {
+		return params, fmt.Errorf("invalid should_start_at_element")
+	}
+	params.ShouldStartAtElement = shouldStartAtElement
+
+	totalElementsToAppendRaw := data.Get("total_elements_to_append").(string)
+	if util.ValidNumber(totalElementsToAppendRaw) == nil {
+		return params, fmt.Errorf("invalid total_elements_to_append")
+	}
+	totalElementsToAppend, err := strconv.ParseUint(totalElementsToAppendRaw, 10, 64)
+	if err != nil {
+		return params, fmt.Errorf("invalid total_elements_to_append")
+	}
+	params.TotalElementsToAppend = totalElementsToAppend
+
+	inputContexts, ok := data.GetOk("contexts")
+	if !ok {
+		return params, fmt.Errorf("invalid contexts")
+	}
+	contextStrs := inputContexts.([]string)
+	params.Contexts = make([]batchcodec.Context, len(contextStrs))
+	for i, s := range contextStrs {
+		var context Context
+		if err := json.Unmarshal([]byte(s), &context); err != nil {
+			return params, fmt.Errorf("invalid contexts")
+		}
+		params.Contexts[i] = batchcodec.Context{
+			NumSequencedTransactions:       uint64(context.NumSequencedTransactions),
+			NumSubsequentQueueTransactions: uint64(context.NumSubsequentQueueTransactions),
+			Timestamp:                      uint64(context.Timestamp),
+			BlockNumber:                    uint64(context.BlockNumber),
+		}
+	}
+
+	inputTransactions, ok := data.GetOk("transactions")
+	if !ok {
+		return params, fmt.Errorf("invalid transactions")
+	}
+	txStrs := inputTransactions.([]string)
+	params.Transactions = make([][]byte, len(txStrs))
+	for i, s := range txStrs {
+		if len(remove0x(s))%2 != 0 {
+			return params, fmt.Errorf("unexpected uneven hex string value in transactions")
+		}
+		params.Transactions[i] = common.FromHex(s)
+	}
+
+	return params, nil
+}
+
+func remove0x(val string) string {
+	return strings.Replace(val, "0x", "", -1)
+}
+
 func (b *PluginBackend) pathOvmAppendSequencerBatch(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 
 	config, err := b.configured(ctx, req)
@@ -299,7 +716,7 @@ func (b *PluginBackend) pathOvmAppendSequencerBatch(ctx context.Context, req *lo
 		return nil, fmt.Errorf("invalid chain ID")
 	}
 
-	client, err := ethclient.Dial(config.getRPCURL())
+	client, err := dialPooledEthClient(ctx, config.getRPCURL())
 	if err != nil {
 		return nil, err
 	}
@@ -325,23 +742,78 @@ func (b *PluginBackend) pathOvmAppendSequencerBatch(ctx context.Context, req *lo
 	if err != nil {
 		return nil, err
 	}
-	// transactOpts needs gas etc. Use supplied gas_price
-	gasPriceRaw := data.Get("gas_price").(string)
-	if gasPriceRaw == "" {
-		return nil, fmt.Errorf("invalid gas_price")
+	// transactOpts needs gas pricing. Use supplied gas_price, or max_fee_per_gas/
+	// max_priority_fee_per_gas for an EIP-1559 dynamic fee transaction.
+	pricing, err := b.resolveFeePricing(ctx, client, data)
+	if err != nil {
+		return nil, err
+	}
+	if pricing.Dynamic {
+		transactOpts.GasFeeCap = pricing.GasFeeCap
+		transactOpts.GasTipCap = pricing.GasTipCap
+	} else {
+		transactOpts.GasPrice = pricing.GasPrice
 	}
-	transactOpts.GasPrice = util.ValidNumber(gasPriceRaw)
 
-	// //transactOpts needs nonce. Use supplied nonce
+	// transactOpts needs a nonce. Use the supplied one, or atomically reserve
+	// the next one via noncemgr so concurrent submissions don't collide.
 	nonceRaw := data.Get("nonce").(string)
+	var reservedNonce uint64
+	var haveReservation bool
 	if nonceRaw == "" {
-		return nil, fmt.Errorf("invalid nonce")
+		onChainNonce, err := client.PendingNonceAt(ctx, account.Address)
+		if err != nil {
+			return nil, err
+		}
+		reservedNonce, err = noncemgr.Reserve(ctx, req.Storage, chainID, account.Address.Hex(), onChainNonce)
+		if err != nil {
+			return nil, err
+		}
+		haveReservation = true
+		transactOpts.Nonce = new(big.Int).SetUint64(reservedNonce)
+	} else {
+		transactOpts.Nonce = util.ValidNumber(nonceRaw)
 	}
 
-	encodedData, err := encode(data)
+	params, err := paramsFromData(data)
+	if err != nil {
+		if haveReservation {
+			releaseNonceReservation(ctx, req.Storage, chainID, account.Address.Hex(), reservedNonce)
+		}
+		return nil, err
+	}
+	encodedData, err := batchcodec.Encode(params)
 	if err != nil {
+		if haveReservation {
+			releaseNonceReservation(ctx, req.Storage, chainID, account.Address.Hex(), reservedNonce)
+		}
 		return nil, err
 	}
+	// Guard against silent corruption in the codec by asserting that decoding
+	// what we just encoded and re-encoding it reproduces the same bytes.
+	// Comparing re-encoded bytes (rather than reflect.DeepEqual on the
+	// structs) sidesteps nil-vs-empty-slice mismatches for legitimate
+	// zero-context/zero-transaction batches, which Optimism allows.
+	decodedParams, err := batchcodec.Decode(encodedData)
+	if err != nil {
+		if haveReservation {
+			releaseNonceReservation(ctx, req.Storage, chainID, account.Address.Hex(), reservedNonce)
+		}
+		return nil, fmt.Errorf("encode/decode roundtrip failed: %w", err)
+	}
+	reencodedData, err := batchcodec.Encode(decodedParams)
+	if err != nil {
+		if haveReservation {
+			releaseNonceReservation(ctx, req.Storage, chainID, account.Address.Hex(), reservedNonce)
+		}
+		return nil, fmt.Errorf("encode/decode roundtrip failed: %w", err)
+	}
+	if !bytes.Equal(encodedData, reencodedData) {
+		if haveReservation {
+			releaseNonceReservation(ctx, req.Storage, chainID, account.Address.Hex(), reservedNonce)
+		}
+		return nil, fmt.Errorf("encode/decode roundtrip mismatch, refusing to submit")
+	}
 
 	json_abi := `[{
       "inputs": [],
@@ -353,8 +825,11 @@ func (b *PluginBackend) pathOvmAppendSequencerBatch(ctx context.Context, req *lo
 
 	abi, _ := abi.JSON(strings.NewReader(json_abi))
 	packed, _ := abi.Pack("appendSequencerBatch")
-	callData := append(packed, common.FromHex(encodedData)...)
+	callData := append(packed, encodedData...)
 	transactOpts.GasLimit = 0
+	// Sign but don't send yet, so a simulate=true request can dry-run the tx
+	// before it's broadcast.
+	transactOpts.NoSend = true
 	ctcSession := &ovm_ctc.OvmCtcSession{
 		Contract:     instance,  // Generic contract caller binding to set the session for
 		CallOpts:     *callOpts, // Call options to use throughout this session
@@ -363,24 +838,142 @@ func (b *PluginBackend) pathOvmAppendSequencerBatch(ctx context.Context, req *lo
 
 	tx, err := ctcSession.RawAppendSequencerBatch(callData)
 	if err != nil {
+		if haveReservation {
+			releaseNonceReservation(ctx, req.Storage, chainID, account.Address.Hex(), reservedNonce)
+		}
+		return nil, err
+	}
+
+	var invariants map[string]interface{}
+	if data.Get("simulate").(bool) {
+		invariants, err = b.simulateAppendSequencerBatch(ctx, client, instance, callOpts, contractAddress, account.Address, tx, params)
+		if err != nil {
+			if haveReservation {
+				releaseNonceReservation(ctx, req.Storage, chainID, account.Address.Hex(), reservedNonce)
+			}
+			return nil, err
+		}
+	}
+
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		if haveReservation {
+			releaseNonceReservation(ctx, req.Storage, chainID, account.Address.Hex(), reservedNonce)
+		}
 		return nil, err
 	}
 
+	// Only record the reservation as submitted once the transaction has
+	// actually been broadcast; recording it any earlier would leave a
+	// reservation permanently marked submitted for a tx that was never sent.
+	if haveReservation {
+		if err := noncemgr.Record(ctx, req.Storage, chainID, account.Address.Hex(), reservedNonce, tx.Hash().Hex(), pricing.recordedGasPrice()); err != nil {
+			return nil, err
+		}
+	}
+
 	var signedTxBuff bytes.Buffer
 	tx.EncodeRLP(&signedTxBuff)
+	responseData := feeResponseData(pricing, map[string]interface{}{
+		"contract":           contractAddress.Hex(),
+		"transaction_hash":   tx.Hash().Hex(),
+		"signed_transaction": hexutil.Encode(signedTxBuff.Bytes()),
+		"from":               account.Address.Hex(),
+		"nonce":              tx.Nonce(),
+		"gas_limit":          tx.Gas(),
+	})
+	for k, v := range invariants {
+		responseData[k] = v
+	}
 	return &logical.Response{
-		Data: map[string]interface{}{
-			"contract":           contractAddress.Hex(),
-			"transaction_hash":   tx.Hash().Hex(),
-			"signed_transaction": hexutil.Encode(signedTxBuff.Bytes()),
-			"from":               account.Address.Hex(),
-			"nonce":              tx.Nonce(),
-			"gas_price":          tx.GasPrice(),
-			"gas_limit":          tx.Gas(),
-		},
+		Data: responseData,
+	}, nil
+}
+
+// simulateAppendSequencerBatch dry-runs tx against the CTC via eth_call and
+// verifies should_start_at_element matches getTotalElements() and that the
+// batch's context queue/sequenced counts are internally consistent with
+// total_elements_to_append, returning the computed invariants (including the
+// queue index the batch would leave the CTC at) for the caller to diff.
+func (b *PluginBackend) simulateAppendSequencerBatch(ctx context.Context, client *ethclient.Client, instance *ovm_ctc.OvmCtc, callOpts *bind.CallOpts, contractAddress, from common.Address, tx *types.Transaction, params batchcodec.AppendSequencerBatchParams) (map[string]interface{}, error) {
+	totalElements, err := instance.GetTotalElements(callOpts)
+	if err != nil {
+		return nil, fmt.Errorf("fetching CTC getTotalElements: %w", err)
+	}
+	if totalElements.Uint64() != params.ShouldStartAtElement {
+		return nil, fmt.Errorf("should_start_at_element %d does not match on-chain getTotalElements() %s, refusing to submit", params.ShouldStartAtElement, totalElements)
+	}
+
+	nextQueueIndex, err := instance.GetNextQueueIndex(callOpts)
+	if err != nil {
+		return nil, fmt.Errorf("fetching CTC getNextQueueIndex: %w", err)
+	}
+
+	var sumSequenced, sumQueued uint64
+	for _, c := range params.Contexts {
+		sumSequenced += c.NumSequencedTransactions
+		sumQueued += c.NumSubsequentQueueTransactions
+	}
+	if sumSequenced+sumQueued != params.TotalElementsToAppend {
+		return nil, fmt.Errorf("sum of context counts (%d sequenced + %d queued = %d) does not match total_elements_to_append %d, refusing to submit",
+			sumSequenced, sumQueued, sumSequenced+sumQueued, params.TotalElementsToAppend)
+	}
+	impliedQueueIndex := new(big.Int).Add(nextQueueIndex, new(big.Int).SetUint64(sumQueued))
+
+	if err := callContractAndDecodeRevert(ctx, client, from, contractAddress, tx.Data()); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"simulated":           true,
+		"total_elements":      totalElements.String(),
+		"next_queue_index":    nextQueueIndex.String(),
+		"implied_queue_index": impliedQueueIndex.String(),
+		"sum_sequenced":       sumSequenced,
+		"sum_queued":          sumQueued,
 	}, nil
 }
 
+// callContractAndDecodeRevert dry-runs data as an eth_call against
+// contractAddress, decoding and returning the revert reason if the node
+// rejects it.
+func callContractAndDecodeRevert(ctx context.Context, client *ethclient.Client, from, contractAddress common.Address, data []byte) error {
+	_, err := client.CallContract(ctx, ethereum.CallMsg{
+		From: from,
+		To:   &contractAddress,
+		Data: data,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("simulation reverted: %s", decodeRevertReason(err))
+	}
+	return nil
+}
+
+// decodeRevertReason extracts a human-readable Error(string) revert reason
+// from an eth_call error, falling back to the raw error text when the node
+// doesn't surface structured revert data.
+func decodeRevertReason(err error) string {
+	if de, ok := err.(rpc.DataError); ok {
+		if data, ok := de.ErrorData().(string); ok {
+			if reason, unpackErr := abi.UnpackRevert(common.FromHex(data)); unpackErr == nil {
+				return reason
+			}
+		}
+	}
+	return err.Error()
+}
+
+const (
+	defaultMinBumpPercent = 10
+	defaultMaxBumpPercent = 200
+)
+
+// pendingTx is a transaction discovered in-flight for an account, together
+// with the nonce it occupies.
+type pendingTx struct {
+	Nonce uint64
+	Tx    *types.Transaction
+}
+
 func (b *PluginBackend) pathOvmClearPendingTransactions(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	log.Print("Clearing pending transactions.")
 
@@ -400,175 +993,454 @@ func (b *PluginBackend) pathOvmClearPendingTransactions(ctx context.Context, req
 		return nil, fmt.Errorf("invalid chain ID")
 	}
 
-	client, err := ethclient.Dial(config.getRPCURL())
+	rpcTimeout, err := parseRPCTimeout(data)
+	if err != nil {
+		return nil, err
+	}
+	rpcMaxBatchSize, err := parseRPCMaxBatchSize(data)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, rpcTimeout)
+	defer cancel()
 
-	// c, err := rpc.DialContext(ctx, config.getRPCURL())
-	// var result hexutil.Uint64
-	// errr := c.CallContext(ctx, &result, "eth_blockNumber")
+	rpcClient, err := dialPooled(ctx, config.getRPCURL())
 	if err != nil {
 		return nil, err
 	}
+	client := ethclient.NewClient(rpcClient)
+
 	walletJSON, err := readWallet(ctx, req, name)
 	if err != nil {
 		return nil, err
 	}
 
 	wallet, account, err := getWalletAndAccount(*walletJSON, accountJSON.Index)
+	if err != nil {
+		return nil, err
+	}
 
+	minBumpPercent, maxBumpPercent, maxFeeCap, err := clearPendingBumpParams(data)
 	if err != nil {
 		return nil, err
 	}
-	pendingNonce, err := client.PendingNonceAt(ctx, account.Address)
-	latestNonce, err := client.NonceAt(ctx, account.Address, nil)
-	if pendingNonce > latestNonce {
-		log.Print("Detected pending transactions. Clearing all transactions!")
-		pendingBlock, err := client.BlockByNumber(ctx, big.NewInt(-1))
-		if err != nil {
-			return nil, err
-		}
-		var txHashes = make([]string, pendingNonce-latestNonce)
-		to := common.HexToAddress(data.Get("address").(string))
-		for _, transaction := range pendingBlock.Body().Transactions {
-			pendingTx, _, _ := client.TransactionByHash(ctx, transaction.Hash())
-			tx := new(types.Transaction)
-			//			rawTxBytes, err := hex.DecodeString(string().Hex())
-			rlp.DecodeBytes(pendingTx.Hash().Bytes(), &tx)
-			msg, err := pendingTx.AsMessage(types.NewEIP2930Signer(chainID), pendingTx.GasFeeCap())
-			if err != nil {
-				return nil, err
-			}
-			if msg.From().Hex() == address {
-				bumpGasPrice := new(big.Int).Add(pendingTx.GasPrice(), new(big.Int).Mul(big.NewInt(70), big.NewInt(params.GWei)))
-				//for i := latestNonce; i <= pendingNonce; i++ {
-				tx := types.NewTransaction(pendingTx.Nonce(), to, big.NewInt(0), pendingTx.Gas(), bumpGasPrice, pendingTx.Data())
-				log.Print(fmt.Sprintf("Sending an existing transaction, bumping Gas Price %v to %v \n", pendingTx.GasPrice(), bumpGasPrice))
-				signedTx, err := wallet.SignTx(*account, tx, chainID)
-				if err != nil {
-					return nil, err
-				}
-				err = client.SendTransaction(context.Background(), signedTx)
-				if err != nil {
-					return nil, err
-				}
-				txHashes[0] = signedTx.Hash().Hex()
-				//}
-			}
-		}
 
-		return &logical.Response{
-			Data: map[string]interface{}{
-				"transaction_hashes": txHashes,
-			},
-		}, nil
-	} else {
+	pending, err := pendingTransactionsForAccount(ctx, rpcClient, client, account.Address, rpcMaxBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) == 0 {
 		log.Print("No pending transactions for this account.")
-		var nilSlice []string
 		return &logical.Response{
 			Data: map[string]interface{}{
-				"transaction_hashes": nilSlice,
+				"transaction_hashes": map[string]string{},
 			},
 		}, nil
 	}
+
+	log.Print(fmt.Sprintf("Detected %d pending transactions. Clearing all transactions!", len(pending)))
+	txHashes := make(map[string]string, len(pending))
+	for _, p := range pending {
+		bumpPercent := minBumpPercent
+		hash, err := b.replacePendingTransaction(ctx, client, req.Storage, wallet, account, chainID, p.Tx, bumpPercent, maxFeeCap)
+		for err != nil && strings.Contains(err.Error(), "replacement transaction underpriced") && bumpPercent < maxBumpPercent {
+			bumpPercent += minBumpPercent
+			if bumpPercent > maxBumpPercent {
+				bumpPercent = maxBumpPercent
+			}
+			hash, err = b.replacePendingTransaction(ctx, client, req.Storage, wallet, account, chainID, p.Tx, bumpPercent, maxFeeCap)
+		}
+		if err != nil {
+			// Surface the replacements already broadcast before this failure
+			// instead of discarding them: the operator needs to know which
+			// nonces are already in flight under a new hash before retrying.
+			resp := &logical.Response{
+				Data: map[string]interface{}{
+					"transaction_hashes": txHashes,
+				},
+			}
+			resp.AddWarning(fmt.Sprintf("stopped clearing pending transactions after nonce %d: %v", p.Nonce, err))
+			return resp, nil
+		}
+		txHashes[strconv.FormatUint(p.Nonce, 10)] = hash
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"transaction_hashes": txHashes,
+		},
+	}, nil
+}
+
+// releaseNonceReservation best-effort releases a noncemgr reservation after a
+// batch submission fails before broadcasting (signing, encoding, simulation
+// or send errors), freeing the nonce back up for the next submission. It
+// logs rather than returning an error so the caller still surfaces the
+// original failure.
+func releaseNonceReservation(ctx context.Context, storage logical.Storage, chainID *big.Int, address string, nonce uint64) {
+	if err := noncemgr.Release(ctx, storage, chainID, address, nonce); err != nil {
+		log.Printf("failed to release nonce reservation %d for %s: %v", nonce, address, err)
+	}
 }
 
-func encode(data *framework.FieldData) (string, error) {
-	shouldStartAtElement, err := encodeShouldStartAtElement(data)
+// nonceManagerContext resolves the chainID, RPC client and account the
+// ovm/nonce/* paths act on from the request's name/address fields, the same
+// way the other OVM paths do.
+func (b *PluginBackend) nonceManagerContext(ctx context.Context, req *logical.Request, data *framework.FieldData) (*ethclient.Client, *big.Int, *accounts.Account, error) {
+	config, err := b.configured(ctx, req)
 	if err != nil {
-		return "", err
+		return nil, nil, nil, err
 	}
-	totalElementsToAppend, err := encodeTotalElementsToAppend(data)
+	address := data.Get("address").(string)
+	name := data.Get("name").(string)
+	accountJSON, err := readAccount(ctx, req, name, address)
+	if err != nil || accountJSON == nil {
+		return nil, nil, nil, fmt.Errorf("error reading address")
+	}
+
+	chainID := util.ValidNumber(config.ChainID)
+	if chainID == nil {
+		return nil, nil, nil, fmt.Errorf("invalid chain ID")
+	}
+
+	client, err := dialPooledEthClient(ctx, config.getRPCURL())
 	if err != nil {
-		return "", err
+		return nil, nil, nil, err
 	}
-	contexts, err := encodeContexts(data)
+
+	walletJSON, err := readWallet(ctx, req, name)
 	if err != nil {
-		return "", err
+		return nil, nil, nil, err
 	}
-	transaction, err := encodeTransactionData(data)
+
+	_, account, err := getWalletAndAccount(*walletJSON, accountJSON.Index)
 	if err != nil {
-		return "", err
+		return nil, nil, nil, err
 	}
 
-	return shouldStartAtElement +
-		totalElementsToAppend +
-		contexts +
-		transaction, nil
+	return client, chainID, &account, nil
 }
 
-func encodeTransactionData(data *framework.FieldData) (string, error) {
-	inputTransactions, ok := data.GetOk("transactions")
-	if !ok {
-		return "", fmt.Errorf("invalid transactions")
+func (b *PluginBackend) pathOvmNonceNext(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	client, chainID, account, err := b.nonceManagerContext(ctx, req, data)
+	if err != nil {
+		return nil, err
 	}
+	onChainNonce, err := client.PendingNonceAt(ctx, account.Address)
+	if err != nil {
+		return nil, err
+	}
+	next, err := noncemgr.Next(ctx, req.Storage, chainID, account.Address.Hex(), onChainNonce)
+	if err != nil {
+		return nil, err
+	}
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"nonce": next,
+		},
+	}, nil
+}
 
-	var encodedTransactionData = ""
-	for _, s := range inputTransactions.([]string) {
-		if len(s)%2 != 0 {
-			return "", fmt.Errorf("unexpected uneven hex string value in transactions")
-		}
-		encodedTransactionData += fmt.Sprintf("%06s", remove0x(fmt.Sprintf("%x", len(remove0x(s))/2))) + remove0x(s)
+func (b *PluginBackend) pathOvmNonceReserve(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	client, chainID, account, err := b.nonceManagerContext(ctx, req, data)
+	if err != nil {
+		return nil, err
+	}
+	onChainNonce, err := client.PendingNonceAt(ctx, account.Address)
+	if err != nil {
+		return nil, err
+	}
+	reserved, err := noncemgr.Reserve(ctx, req.Storage, chainID, account.Address.Hex(), onChainNonce)
+	if err != nil {
+		return nil, err
 	}
-	return encodedTransactionData, nil
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"nonce": reserved,
+		},
+	}, nil
 }
 
-func encodeContexts(data *framework.FieldData) (string, error) {
-	inputContexts, ok := data.GetOk("contexts")
-	if !ok {
-		return "", fmt.Errorf("invalid contexts")
+func (b *PluginBackend) pathOvmNonceRelease(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	_, chainID, account, err := b.nonceManagerContext(ctx, req, data)
+	if err != nil {
+		return nil, err
 	}
-	//contexts
-	var contexts = make([]Context, len(inputContexts.([]string)))
-	for i, s := range inputContexts.([]string) {
-		var context Context
-		json.Unmarshal([]byte(s), &context)
-		contexts[i] = context
+	nonceRaw := data.Get("nonce").(string)
+	if nonceRaw == "" {
+		return nil, fmt.Errorf("invalid nonce")
 	}
-	encodedContextsHeader := encodeHex(int64(len(contexts)), 6)
-	var encodedContexts = ""
-	for _, s := range contexts {
-		encodedContexts += encodeBatchContext(s)
+	nonce := util.ValidNumber(nonceRaw)
+	if nonce == nil {
+		return nil, fmt.Errorf("invalid nonce")
+	}
+	if err := noncemgr.Release(ctx, req.Storage, chainID, account.Address.Hex(), nonce.Uint64()); err != nil {
+		return nil, err
 	}
-	encodedContexts = encodedContextsHeader + encodedContexts
-	return encodedContexts, nil
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"nonce": nonce.Uint64(),
+		},
+	}, nil
 }
 
-func encodeTotalElementsToAppend(data *framework.FieldData) (string, error) {
-	dataTotalElementsToAppend := data.Get("total_elements_to_append").(string)
-	validNumber := util.ValidNumber(dataTotalElementsToAppend)
-	if validNumber == nil {
-		return "", fmt.Errorf("invalid total_elements_to_append")
+func (b *PluginBackend) pathOvmNonceInflight(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	client, chainID, account, err := b.nonceManagerContext(ctx, req, data)
+	if err != nil {
+		return nil, err
+	}
+	inflight, err := noncemgr.Inflight(ctx, req.Storage, client, chainID, account.Address.Hex())
+	if err != nil {
+		return nil, err
+	}
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"inflight": inflight,
+		},
+	}, nil
+}
+
+// clearPendingBumpParams reads and validates min_bump_percent, max_bump_percent
+// and max_fee_cap off data, applying their defaults when omitted.
+func clearPendingBumpParams(data *framework.FieldData) (minBumpPercent, maxBumpPercent int, maxFeeCap *big.Int, err error) {
+	minBumpPercent = defaultMinBumpPercent
+	if v := data.Get("min_bump_percent").(string); v != "" {
+		minBumpPercent, err = strconv.Atoi(v)
+		if err != nil || minBumpPercent <= 0 {
+			return 0, 0, nil, fmt.Errorf("invalid min_bump_percent")
+		}
+	}
+
+	maxBumpPercent = defaultMaxBumpPercent
+	if v := data.Get("max_bump_percent").(string); v != "" {
+		maxBumpPercent, err = strconv.Atoi(v)
+		if err != nil || maxBumpPercent < minBumpPercent {
+			return 0, 0, nil, fmt.Errorf("invalid max_bump_percent")
+		}
+	}
+
+	if v := data.Get("max_fee_cap").(string); v != "" {
+		maxFeeCap = util.ValidNumber(v)
+		if maxFeeCap == nil {
+			return 0, 0, nil, fmt.Errorf("invalid max_fee_cap")
+		}
+	}
+
+	return minBumpPercent, maxBumpPercent, maxFeeCap, nil
+}
+
+// pendingTransactionsForAccount enumerates the account's in-flight
+// transactions via the txpool_content RPC, keyed by nonce. RPCs that don't
+// expose txpool_content (e.g. behind a load balancer) fall back to the
+// node's pending block (eth_getBlockByNumber("pending", ...)), the only
+// other JSON-RPC view onto unmined transactions. Confirmed (mined) blocks
+// can never contain a nonce in [latestNonce, pendingNonce) by definition, so
+// there's no walking further back to fall through to: if the pending block
+// doesn't account for every nonce in that range, the gap is returned as an
+// error rather than silently reporting fewer pending transactions than
+// actually exist.
+func pendingTransactionsForAccount(ctx context.Context, rpcClient *rpc.Client, client *ethclient.Client, address common.Address, maxBatchSize int) ([]pendingTx, error) {
+	var content struct {
+		Pending map[string]map[string]*types.Transaction `json:"pending"`
+	}
+	if err := rpcClient.CallContext(ctx, &content, "txpool_content"); err == nil {
+		if byNonce, ok := content.Pending[address.Hex()]; ok && len(byNonce) > 0 {
+			txs := make([]pendingTx, 0, len(byNonce))
+			for nonceStr, tx := range byNonce {
+				nonce, convErr := strconv.ParseUint(nonceStr, 0, 64)
+				if convErr != nil {
+					continue
+				}
+				txs = append(txs, pendingTx{Nonce: nonce, Tx: tx})
+			}
+			sort.Slice(txs, func(i, j int) bool { return txs[i].Nonce < txs[j].Nonce })
+			return txs, nil
+		}
+	}
+
+	latestNonce, pendingNonce, err := nonceWindow(ctx, rpcClient, address, maxBatchSize)
+	if err != nil {
+		return nil, err
 	}
-	inputTotalElementsToAppend, err := strconv.ParseInt(dataTotalElementsToAppend, 10, 64)
+	if pendingNonce <= latestNonce {
+		return nil, nil
+	}
+
+	pendingBlock, err := client.BlockByNumber(ctx, big.NewInt(rpc.PendingBlockNumber.Int64()))
 	if err != nil {
-		return "", fmt.Errorf("%d total_elements_to_append of type %T", inputTotalElementsToAppend, inputTotalElementsToAppend)
+		return nil, fmt.Errorf("txpool_content unavailable and fetching the pending block failed: %w", err)
+	}
+
+	found := make(map[uint64]*types.Transaction, pendingNonce-latestNonce)
+	if pendingBlock != nil {
+		for _, tx := range pendingBlock.Transactions() {
+			signer := types.LatestSignerForChainID(tx.ChainId())
+			from, senderErr := types.Sender(signer, tx)
+			if senderErr != nil || from != address {
+				continue
+			}
+			if tx.Nonce() >= latestNonce && tx.Nonce() < pendingNonce {
+				found[tx.Nonce()] = tx
+			}
+		}
+	}
+
+	if uint64(len(found)) != pendingNonce-latestNonce {
+		return nil, fmt.Errorf("account has %d pending transaction(s) (nonce %d..%d) but txpool_content is unavailable and the pending block only accounts for %d of them; this RPC cannot be cleared via the scanning fallback", pendingNonce-latestNonce, latestNonce, pendingNonce-1, len(found))
+	}
+
+	txs := make([]pendingTx, 0, len(found))
+	for nonce, tx := range found {
+		txs = append(txs, pendingTx{Nonce: nonce, Tx: tx})
 	}
-	encodedTotalElementsToAppend := encodeHex(inputTotalElementsToAppend, 6)
-	return encodedTotalElementsToAppend, nil
+	sort.Slice(txs, func(i, j int) bool { return txs[i].Nonce < txs[j].Nonce })
+	return txs, nil
 }
 
-func encodeShouldStartAtElement(data *framework.FieldData) (string, error) {
-	dataEncodeShouldStartAtElement := data.Get("should_start_at_element").(string)
-	validNumber := util.ValidNumber(dataEncodeShouldStartAtElement)
-	if validNumber == nil {
-		return "", fmt.Errorf("invalid should_start_at_element")
+// replacePendingTransaction signs and sends a zero-value self-transfer at
+// orig's nonce, bumping its tip/fee cap (for dynamic-fee transactions) or
+// gas price (for legacy ones) by bumpPercent, and returns its hash. If orig's
+// nonce was reserved through noncemgr, the reservation is marked Replaced
+// with the new hash so Reconcile keeps tracking it to mined instead of the
+// now-dead original hash.
+func (b *PluginBackend) replacePendingTransaction(ctx context.Context, client *ethclient.Client, storage logical.Storage, wallet accounts.Wallet, account *accounts.Account, chainID *big.Int, orig *types.Transaction, bumpPercent int, maxFeeCap *big.Int) (string, error) {
+	var replacement *types.Transaction
+	if orig.Type() == types.DynamicFeeTxType {
+		gasTipCap := bumpByPercent(orig.GasTipCap(), bumpPercent)
+		gasFeeCap := bumpByPercent(orig.GasFeeCap(), bumpPercent)
+		if maxFeeCap != nil && gasFeeCap.Cmp(maxFeeCap) > 0 {
+			return "", fmt.Errorf("bumped gas fee cap %s exceeds max_fee_cap %s", gasFeeCap, maxFeeCap)
+		}
+		replacement = types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     orig.Nonce(),
+			GasTipCap: gasTipCap,
+			GasFeeCap: gasFeeCap,
+			Gas:       orig.Gas(),
+			To:        &account.Address,
+			Value:     big.NewInt(0),
+		})
+	} else {
+		gasPrice := bumpByPercent(orig.GasPrice(), bumpPercent)
+		if maxFeeCap != nil && gasPrice.Cmp(maxFeeCap) > 0 {
+			return "", fmt.Errorf("bumped gas price %s exceeds max_fee_cap %s", gasPrice, maxFeeCap)
+		}
+		replacement = types.NewTx(&types.LegacyTx{
+			Nonce:    orig.Nonce(),
+			GasPrice: gasPrice,
+			Gas:      orig.Gas(),
+			To:       &account.Address,
+			Value:    big.NewInt(0),
+		})
 	}
-	inputEncodeShouldStartAtElement, err := strconv.ParseInt(dataEncodeShouldStartAtElement, 10, 64)
+
+	signedTx, err := wallet.SignTx(*account, replacement, chainID)
 	if err != nil {
-		return "", fmt.Errorf("%d should_start_at_element of type %T", inputEncodeShouldStartAtElement, inputEncodeShouldStartAtElement)
+		return "", err
+	}
+
+	log.Print(fmt.Sprintf("Replacing pending transaction at nonce %d with a %d%% bump", orig.Nonce(), bumpPercent))
+	if err := client.SendTransaction(ctx, signedTx); err != nil {
+		return "", err
+	}
+
+	if err := noncemgr.MarkReplaced(ctx, storage, chainID, account.Address.Hex(), orig.Nonce(), signedTx.Hash().Hex()); err != nil {
+		log.Printf("failed to mark nonce %d replaced for %s: %v", orig.Nonce(), account.Address.Hex(), err)
 	}
-	encodeShouldStartAtElement := encodeHex(inputEncodeShouldStartAtElement, 10)
-	return encodeShouldStartAtElement, nil
+	return signedTx.Hash().Hex(), nil
 }
 
-func remove0x(val string) string {
-	return strings.Replace(val, "0x", "", -1)
+// bumpByPercent returns val increased by percent, e.g. bumpByPercent(100, 10) == 110.
+func bumpByPercent(val *big.Int, percent int) *big.Int {
+	bumped := new(big.Int).Mul(val, big.NewInt(int64(100+percent)))
+	return bumped.Div(bumped, big.NewInt(100))
 }
 
-func encodeHex(val int64, len int) string {
-	hex := fmt.Sprintf("%x", val)
-	return fmt.Sprintf("%0"+strconv.Itoa(len)+"s", hex)
+// feePricing holds the gas pricing resolved for a transaction, either a
+// legacy GasPrice or an EIP-1559 GasFeeCap/GasTipCap pair.
+type feePricing struct {
+	Dynamic   bool
+	GasPrice  *big.Int
+	GasFeeCap *big.Int
+	GasTipCap *big.Int
+	BaseFee   *big.Int
 }
 
-func encodeBatchContext(context Context) string {
-	return (encodeHex(context.NumSequencedTransactions, 6) + encodeHex(context.NumSubsequentQueueTransactions, 6) + encodeHex(context.Timestamp, 10) + encodeHex(context.BlockNumber, 10))
-}
\ No newline at end of file
+// recordedGasPrice returns the value to persist in noncemgr as this
+// transaction's gas price: the fee cap for dynamic-fee txes, the gas price
+// for legacy ones.
+func (p *feePricing) recordedGasPrice() string {
+	if p.Dynamic {
+		return p.GasFeeCap.String()
+	}
+	return p.GasPrice.String()
+}
+
+// resolveFeePricing reads gas_price, max_fee_per_gas, max_priority_fee_per_gas
+// and gas_tip_cap_suggest off data and resolves the pricing to apply to a
+// transaction. When max_fee_per_gas is supplied it builds EIP-1559 pricing,
+// checking that the chain has activated London by requiring a base fee on
+// the latest header, and otherwise falls back to legacy gas_price pricing.
+func (b *PluginBackend) resolveFeePricing(ctx context.Context, client *ethclient.Client, data *framework.FieldData) (*feePricing, error) {
+	maxFeePerGasRaw := data.Get("max_fee_per_gas").(string)
+	if maxFeePerGasRaw == "" {
+		gasPriceRaw := data.Get("gas_price").(string)
+		if gasPriceRaw == "" {
+			return nil, fmt.Errorf("invalid gas_price")
+		}
+		gasPrice := util.ValidNumber(gasPriceRaw)
+		if gasPrice == nil {
+			return nil, fmt.Errorf("invalid gas_price")
+		}
+		return &feePricing{GasPrice: gasPrice}, nil
+	}
+
+	gasFeeCap := util.ValidNumber(maxFeePerGasRaw)
+	if gasFeeCap == nil {
+		return nil, fmt.Errorf("invalid max_fee_per_gas")
+	}
+
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if header.BaseFee == nil {
+		return nil, fmt.Errorf("chain does not support EIP-1559 dynamic fee transactions")
+	}
+
+	maxPriorityFeePerGasRaw := data.Get("max_priority_fee_per_gas").(string)
+	var gasTipCap *big.Int
+	if maxPriorityFeePerGasRaw == "" {
+		if !data.Get("gas_tip_cap_suggest").(bool) {
+			return nil, fmt.Errorf("invalid max_priority_fee_per_gas")
+		}
+		gasTipCap, err = client.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		gasTipCap = util.ValidNumber(maxPriorityFeePerGasRaw)
+		if gasTipCap == nil {
+			return nil, fmt.Errorf("invalid max_priority_fee_per_gas")
+		}
+	}
+
+	return &feePricing{
+		Dynamic:   true,
+		GasFeeCap: gasFeeCap,
+		GasTipCap: gasTipCap,
+		BaseFee:   header.BaseFee,
+	}, nil
+}
+
+// feeResponseData merges the effective gas pricing into a response data map.
+func feeResponseData(pricing *feePricing, data map[string]interface{}) map[string]interface{} {
+	if pricing.Dynamic {
+		data["base_fee"] = pricing.BaseFee.String()
+		data["gas_fee_cap"] = pricing.GasFeeCap.String()
+		data["gas_tip_cap"] = pricing.GasTipCap.String()
+	} else {
+		data["gas_price"] = pricing.GasPrice.String()
+	}
+	return data
+}
@@ -0,0 +1,307 @@
+// Copyright (C) Immutability, LLC - All Rights Reserved
+// Unauthorized copying of this file, via any medium is strictly prohibited
+// Proprietary and confidential
+
+// Package noncemgr tracks, per (chainID, address), every nonce this plugin
+// has reserved and the transaction signed for it. It lets concurrent OVM
+// batch submissions agree on the next nonce without racing against each
+// other, and gives operators visibility into what's currently in flight.
+package noncemgr
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// Status is the lifecycle state of a reserved nonce.
+type Status string
+
+const (
+	// StatusReserved means the nonce has been handed out but no transaction
+	// has been signed for it yet.
+	StatusReserved Status = "reserved"
+	// StatusSubmitted means a transaction was signed and sent for this nonce.
+	StatusSubmitted Status = "submitted"
+	// StatusMined means a receipt was found for the recorded transaction hash.
+	StatusMined Status = "mined"
+	// StatusReplaced means this nonce's original transaction was superseded
+	// by a later one (e.g. a gas-price bump) before being mined.
+	StatusReplaced Status = "replaced"
+	// StatusDropped means the nonce was released through Release without
+	// ever being mined, e.g. after a signing failure. Reconcile never sets
+	// it; a transaction that is never mined stays submitted or replaced.
+	StatusDropped Status = "dropped"
+)
+
+// Entry is a single nonce reservation and, once known, the transaction
+// signed for it.
+type Entry struct {
+	Nonce       uint64 `json:"nonce"`
+	Hash        string `json:"hash,omitempty"`
+	GasPrice    string `json:"gas_price,omitempty"`
+	SubmittedAt int64  `json:"submitted_at,omitempty"`
+	Status      Status `json:"status"`
+}
+
+const storagePrefix = "noncemgr/"
+
+func storageKey(chainID *big.Int, address string) string {
+	return storagePrefix + chainID.String() + "/" + strings.ToLower(address)
+}
+
+// record is the per-(chainID,address) persisted state.
+type record struct {
+	Entries []Entry `json:"entries"`
+}
+
+func load(ctx context.Context, storage logical.Storage, chainID *big.Int, address string) (*record, error) {
+	storageEntry, err := storage.Get(ctx, storageKey(chainID, address))
+	if err != nil {
+		return nil, err
+	}
+	rec := &record{}
+	if storageEntry == nil {
+		return rec, nil
+	}
+	if err := storageEntry.DecodeJSON(rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+func save(ctx context.Context, storage logical.Storage, chainID *big.Int, address string, rec *record) error {
+	storageEntry, err := logical.StorageEntryJSON(storageKey(chainID, address), rec)
+	if err != nil {
+		return err
+	}
+	return storage.Put(ctx, storageEntry)
+}
+
+// locks serializes Reserve calls per (chainID,address) within this process,
+// closing the window where two concurrent requests could both read the same
+// "next" nonce before either persists its reservation. Vault's logical.Storage
+// has no atomic compare-and-swap, so this in-memory lock is the only guard
+// against a same-process race; cross-process races still rely on the caller
+// retrying on "nonce too low" from the RPC.
+var locks sync.Map // map[string]*sync.Mutex
+
+func lockFor(key string) *sync.Mutex {
+	mu, _ := locks.LoadOrStore(key, &sync.Mutex{})
+	return mu.(*sync.Mutex)
+}
+
+// highestUnreleased returns the highest nonce not marked dropped, or -1 if
+// there isn't one.
+func (r *record) highestUnreleased() int64 {
+	highest := int64(-1)
+	for _, e := range r.Entries {
+		if e.Status == StatusDropped {
+			continue
+		}
+		if int64(e.Nonce) > highest {
+			highest = int64(e.Nonce)
+		}
+	}
+	return highest
+}
+
+func (r *record) indexOf(nonce uint64) int {
+	for i, e := range r.Entries {
+		if e.Nonce == nonce {
+			return i
+		}
+	}
+	return -1
+}
+
+// Next previews the nonce Reserve would hand out next, without reserving it.
+func Next(ctx context.Context, storage logical.Storage, chainID *big.Int, address string, onChainNonce uint64) (uint64, error) {
+	rec, err := load(ctx, storage, chainID, address)
+	if err != nil {
+		return 0, err
+	}
+	next := onChainNonce
+	if highest := rec.highestUnreleased(); highest >= 0 && uint64(highest)+1 > next {
+		next = uint64(highest) + 1
+	}
+	return next, nil
+}
+
+// Reserve atomically picks the next nonce for (chainID, address) as
+// max(onChainNonce, highest unreleased reservation + 1), persists it with
+// status "reserved", and returns it. onChainNonce should be the account's
+// PendingNonceAt value. Callers sign their transaction after reserving and
+// should call Record to attach the resulting hash, or Release if signing
+// fails.
+func Reserve(ctx context.Context, storage logical.Storage, chainID *big.Int, address string, onChainNonce uint64) (uint64, error) {
+	key := storageKey(chainID, address)
+	mu := lockFor(key)
+	mu.Lock()
+	defer mu.Unlock()
+
+	rec, err := load(ctx, storage, chainID, address)
+	if err != nil {
+		return 0, err
+	}
+
+	next := onChainNonce
+	if highest := rec.highestUnreleased(); highest >= 0 && uint64(highest)+1 > next {
+		next = uint64(highest) + 1
+	}
+
+	if i := rec.indexOf(next); i >= 0 {
+		// next is above every unreleased reservation, so an existing entry
+		// for it can only be a dropped one; reuse it instead of adding a
+		// second entry for the same nonce.
+		rec.Entries[i] = Entry{Nonce: next, Status: StatusReserved}
+	} else {
+		rec.Entries = append(rec.Entries, Entry{Nonce: next, Status: StatusReserved})
+	}
+	if err := save(ctx, storage, chainID, address, rec); err != nil {
+		return 0, err
+	}
+	return next, nil
+}
+
+// Release marks a reservation dropped (e.g. after a signing or submission
+// failure). Reserve hands the nonce back out only while no higher nonce is
+// still reserved, submitted or replaced.
+func Release(ctx context.Context, storage logical.Storage, chainID *big.Int, address string, nonce uint64) error {
+	key := storageKey(chainID, address)
+	mu := lockFor(key)
+	mu.Lock()
+	defer mu.Unlock()
+
+	rec, err := load(ctx, storage, chainID, address)
+	if err != nil {
+		return err
+	}
+	i := rec.indexOf(nonce)
+	if i < 0 {
+		return fmt.Errorf("no reservation found for nonce %d", nonce)
+	}
+	rec.Entries[i].Status = StatusDropped
+	return save(ctx, storage, chainID, address, rec)
+}
+
+// Record attaches the signed transaction's hash and gas price to a
+// reservation and marks it submitted.
+func Record(ctx context.Context, storage logical.Storage, chainID *big.Int, address string, nonce uint64, hash, gasPrice string) error {
+	key := storageKey(chainID, address)
+	mu := lockFor(key)
+	mu.Lock()
+	defer mu.Unlock()
+
+	rec, err := load(ctx, storage, chainID, address)
+	if err != nil {
+		return err
+	}
+	i := rec.indexOf(nonce)
+	if i < 0 {
+		return fmt.Errorf("no reservation found for nonce %d", nonce)
+	}
+	rec.Entries[i].Hash = hash
+	rec.Entries[i].GasPrice = gasPrice
+	rec.Entries[i].SubmittedAt = time.Now().Unix()
+	rec.Entries[i].Status = StatusSubmitted
+	return save(ctx, storage, chainID, address, rec)
+}
+
+// MarkReplaced marks nonce's reservation as superseded by a later transaction
+// (e.g. a gas-price bump from clearPendingTransactions), recording newHash so
+// Reconcile keeps following it to mined rather than the dead original hash.
+// It is a no-op if nonce has no reservation, since not every pending
+// transaction this plugin replaces was necessarily reserved through
+// noncemgr in the first place.
+func MarkReplaced(ctx context.Context, storage logical.Storage, chainID *big.Int, address string, nonce uint64, newHash string) error {
+	key := storageKey(chainID, address)
+	mu := lockFor(key)
+	mu.Lock()
+	defer mu.Unlock()
+
+	rec, err := load(ctx, storage, chainID, address)
+	if err != nil {
+		return err
+	}
+	i := rec.indexOf(nonce)
+	if i < 0 {
+		return nil
+	}
+	rec.Entries[i].Status = StatusReplaced
+	rec.Entries[i].Hash = newHash
+	rec.Entries[i].SubmittedAt = time.Now().Unix()
+	return save(ctx, storage, chainID, address, rec)
+}
+
+// Inflight returns every reservation still reserved or submitted, sorted by
+// nonce; mined, dropped and replaced entries are left out. It first reconciles submitted entries against on-chain receipts via
+// client, marking anything that's since been mined.
+func Inflight(ctx context.Context, storage logical.Storage, client *ethclient.Client, chainID *big.Int, address string) ([]Entry, error) {
+	if err := Reconcile(ctx, storage, client, chainID, address); err != nil {
+		return nil, err
+	}
+
+	rec, err := load(ctx, storage, chainID, address)
+	if err != nil {
+		return nil, err
+	}
+
+	var inflight []Entry
+	for _, e := range rec.Entries {
+		if e.Status == StatusMined || e.Status == StatusDropped || e.Status == StatusReplaced {
+			continue
+		}
+		inflight = append(inflight, e)
+	}
+	sort.Slice(inflight, func(i, j int) bool { return inflight[i].Nonce < inflight[j].Nonce })
+	return inflight, nil
+}
+
+// Reconcile looks up a receipt for every submitted or replaced entry's hash,
+// marking it mined when found. A replaced entry carries the hash of the
+// transaction that superseded the original (see MarkReplaced), so this is
+// what lets a replaced nonce ever settle into StatusMined instead of sitting
+// as StatusReplaced forever. Entries that are still only reserved (no hash
+// attached, e.g. the request that reserved them never completed) or whose
+// transaction was never mined are left untouched for a future reconcile pass
+// to retry.
+func Reconcile(ctx context.Context, storage logical.Storage, client *ethclient.Client, chainID *big.Int, address string) error {
+	key := storageKey(chainID, address)
+	mu := lockFor(key)
+	mu.Lock()
+	defer mu.Unlock()
+
+	rec, err := load(ctx, storage, chainID, address)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	for i, e := range rec.Entries {
+		if (e.Status != StatusSubmitted && e.Status != StatusReplaced) || e.Hash == "" {
+			continue
+		}
+		receipt, err := client.TransactionReceipt(ctx, common.HexToHash(e.Hash))
+		if err != nil {
+			continue
+		}
+		if receipt != nil {
+			rec.Entries[i].Status = StatusMined
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	return save(ctx, storage, chainID, address, rec)
+}
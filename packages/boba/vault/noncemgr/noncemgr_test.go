@@ -0,0 +1,176 @@
+// Copyright (C) Immutability, LLC - All Rights Reserved
+// Unauthorized copying of this file, via any medium is strictly prohibited
+// Proprietary and confidential
+
+package noncemgr
+
+import (
+	"context"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// memStorage is a minimal in-memory logical.Storage for exercising noncemgr
+// without a real Vault barrel.
+type memStorage struct {
+	entries map[string]*logical.StorageEntry
+}
+
+func newMemStorage() *memStorage {
+	return &memStorage{entries: make(map[string]*logical.StorageEntry)}
+}
+
+func (m *memStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for k := range m.entries {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, strings.TrimPrefix(k, prefix))
+		}
+	}
+	return keys, nil
+}
+
+func (m *memStorage) Get(ctx context.Context, key string) (*logical.StorageEntry, error) {
+	return m.entries[key], nil
+}
+
+func (m *memStorage) Put(ctx context.Context, entry *logical.StorageEntry) error {
+	m.entries[entry.Key] = entry
+	return nil
+}
+
+func (m *memStorage) Delete(ctx context.Context, key string) error {
+	delete(m.entries, key)
+	return nil
+}
+
+var testChainID = big.NewInt(1)
+
+// unreachableClient returns an *ethclient.Client dialed at an address nothing
+// is listening on, so every call (e.g. TransactionReceipt) fails the way it
+// would for a transaction that hasn't been mined yet, without needing a live
+// Ethereum node.
+func unreachableClient(t *testing.T) *ethclient.Client {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	client, err := ethclient.Dial("http://" + addr)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	return client
+}
+
+func TestReserveReleaseReusesNonce(t *testing.T) {
+	ctx := context.Background()
+	storage := newMemStorage()
+
+	first, err := Reserve(ctx, storage, testChainID, "0xabc", 5)
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if first != 5 {
+		t.Fatalf("expected nonce 5, got %d", first)
+	}
+
+	second, err := Reserve(ctx, storage, testChainID, "0xabc", 5)
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if second != 6 {
+		t.Fatalf("expected nonce 6, got %d", second)
+	}
+
+	if err := Release(ctx, storage, testChainID, "0xabc", second); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+
+	next, err := Next(ctx, storage, testChainID, "0xabc", 5)
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if next != 6 {
+		t.Fatalf("expected released nonce 6 to be reusable, got %d", next)
+	}
+
+	reserved, err := Reserve(ctx, storage, testChainID, "0xabc", 5)
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if reserved != 6 {
+		t.Fatalf("expected Reserve to reuse released nonce 6, got %d", reserved)
+	}
+}
+
+func TestReconcileWithNoReceiptLeavesEntryInflight(t *testing.T) {
+	ctx := context.Background()
+	storage := newMemStorage()
+
+	nonce, err := Reserve(ctx, storage, testChainID, "0xdef", 0)
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if err := Record(ctx, storage, testChainID, "0xdef", nonce, "0xhash", "1000000000"); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	client := unreachableClient(t)
+
+	inflight, err := Inflight(ctx, storage, client, testChainID, "0xdef")
+	if err != nil {
+		t.Fatalf("Inflight: %v", err)
+	}
+	if len(inflight) != 1 || inflight[0].Nonce != nonce || inflight[0].Status != StatusSubmitted {
+		t.Fatalf("expected nonce %d still inflight as submitted, got %+v", nonce, inflight)
+	}
+}
+
+func TestMarkReplacedExcludedFromInflight(t *testing.T) {
+	ctx := context.Background()
+	storage := newMemStorage()
+
+	nonce, err := Reserve(ctx, storage, testChainID, "0x123", 0)
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if err := Record(ctx, storage, testChainID, "0x123", nonce, "0xoriginal", "1"); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if err := MarkReplaced(ctx, storage, testChainID, "0x123", nonce, "0xreplacement"); err != nil {
+		t.Fatalf("MarkReplaced: %v", err)
+	}
+
+	rec, err := load(ctx, storage, testChainID, "0x123")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if rec.Entries[0].Status != StatusReplaced || rec.Entries[0].Hash != "0xreplacement" {
+		t.Fatalf("expected entry marked replaced with new hash, got %+v", rec.Entries[0])
+	}
+
+	client := unreachableClient(t)
+	inflight, err := Inflight(ctx, storage, client, testChainID, "0x123")
+	if err != nil {
+		t.Fatalf("Inflight: %v", err)
+	}
+	if len(inflight) != 0 {
+		t.Fatalf("expected replaced nonce to be excluded from inflight, got %+v", inflight)
+	}
+}
+
+func TestMarkReplacedWithNoReservationIsNoop(t *testing.T) {
+	ctx := context.Background()
+	storage := newMemStorage()
+	if err := MarkReplaced(ctx, storage, testChainID, "0x999", 42, "0xnew"); err != nil {
+		t.Fatalf("expected no-op for an untracked nonce, got error: %v", err)
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright (C) Immutability, LLC - All Rights Reserved
+// Unauthorized copying of this file, via any medium is strictly prohibited
+// Proprietary and confidential
+
+// Package batchcodec implements the custom (non-ABI) calldata layout the L1
+// CTC contract's appendSequencerBatch function expects: a handful of
+// fixed-width big-endian integers followed by length-prefixed transactions.
+// See https://github.com/ethereum-optimism/optimism CanonicalTransactionChain.sol
+// for the layout this mirrors.
+package batchcodec
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	shouldStartAtElementBytes  = 5
+	totalElementsToAppendBytes = 3
+	contextsHeaderBytes        = 3
+	contextCountBytes          = 3
+	contextTimeBytes           = 5
+	txLengthBytes              = 3
+)
+
+// Context mirrors the L1 CTC contract's BatchContext struct.
+type Context struct {
+	NumSequencedTransactions       uint64
+	NumSubsequentQueueTransactions uint64
+	Timestamp                      uint64
+	BlockNumber                    uint64
+}
+
+// AppendSequencerBatchParams is the decoded form of the calldata appended
+// after the appendSequencerBatch function selector.
+type AppendSequencerBatchParams struct {
+	ShouldStartAtElement  uint64
+	TotalElementsToAppend uint64
+	Contexts              []Context
+	Transactions          [][]byte
+}
+
+// Encode serializes params into the calldata layout appendSequencerBatch
+// expects. Unlike a string-concatenation based encoder, every fixed-width
+// field is bounds-checked, so a value that would overflow its slot returns
+// an error instead of being silently truncated.
+func Encode(params AppendSequencerBatchParams) ([]byte, error) {
+	var buf []byte
+
+	shouldStartAtElement, err := encodeUint(params.ShouldStartAtElement, shouldStartAtElementBytes, "should_start_at_element")
+	if err != nil {
+		return nil, err
+	}
+	buf = append(buf, shouldStartAtElement...)
+
+	totalElementsToAppend, err := encodeUint(params.TotalElementsToAppend, totalElementsToAppendBytes, "total_elements_to_append")
+	if err != nil {
+		return nil, err
+	}
+	buf = append(buf, totalElementsToAppend...)
+
+	contextsHeader, err := encodeUint(uint64(len(params.Contexts)), contextsHeaderBytes, "contexts length")
+	if err != nil {
+		return nil, err
+	}
+	buf = append(buf, contextsHeader...)
+
+	for i, c := range params.Contexts {
+		encoded, err := encodeContext(c)
+		if err != nil {
+			return nil, fmt.Errorf("context %d: %w", i, err)
+		}
+		buf = append(buf, encoded...)
+	}
+
+	for i, tx := range params.Transactions {
+		length, err := encodeUint(uint64(len(tx)), txLengthBytes, "transaction length")
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i, err)
+		}
+		buf = append(buf, length...)
+		buf = append(buf, tx...)
+	}
+
+	return buf, nil
+}
+
+func encodeContext(c Context) ([]byte, error) {
+	var buf []byte
+
+	numSequenced, err := encodeUint(c.NumSequencedTransactions, contextCountBytes, "num_sequenced_transactions")
+	if err != nil {
+		return nil, err
+	}
+	buf = append(buf, numSequenced...)
+
+	numSubsequent, err := encodeUint(c.NumSubsequentQueueTransactions, contextCountBytes, "num_subsequent_queue_transactions")
+	if err != nil {
+		return nil, err
+	}
+	buf = append(buf, numSubsequent...)
+
+	timestamp, err := encodeUint(c.Timestamp, contextTimeBytes, "timestamp")
+	if err != nil {
+		return nil, err
+	}
+	buf = append(buf, timestamp...)
+
+	blockNumber, err := encodeUint(c.BlockNumber, contextTimeBytes, "block_number")
+	if err != nil {
+		return nil, err
+	}
+	buf = append(buf, blockNumber...)
+
+	return buf, nil
+}
+
+// encodeUint big-endian encodes val into width bytes, erroring if val
+// doesn't fit instead of silently truncating it.
+func encodeUint(val uint64, width int, field string) ([]byte, error) {
+	if width < 8 && val>>uint(width*8) != 0 {
+		return nil, fmt.Errorf("%s overflows %d-byte field: %d", field, width, val)
+	}
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], val)
+	return b[8-width:], nil
+}
+
+// Decode parses calldata produced by Encode back into an
+// AppendSequencerBatchParams, validating lengths as it goes.
+func Decode(data []byte) (AppendSequencerBatchParams, error) {
+	var params AppendSequencerBatchParams
+	offset := 0
+
+	shouldStartAtElement, err := decodeUint(data, &offset, shouldStartAtElementBytes, "should_start_at_element")
+	if err != nil {
+		return params, err
+	}
+	params.ShouldStartAtElement = shouldStartAtElement
+
+	totalElementsToAppend, err := decodeUint(data, &offset, totalElementsToAppendBytes, "total_elements_to_append")
+	if err != nil {
+		return params, err
+	}
+	params.TotalElementsToAppend = totalElementsToAppend
+
+	contextCount, err := decodeUint(data, &offset, contextsHeaderBytes, "contexts length")
+	if err != nil {
+		return params, err
+	}
+
+	params.Contexts = make([]Context, contextCount)
+	for i := range params.Contexts {
+		c, err := decodeContext(data, &offset)
+		if err != nil {
+			return params, fmt.Errorf("context %d: %w", i, err)
+		}
+		params.Contexts[i] = c
+	}
+
+	for offset < len(data) {
+		length, err := decodeUint(data, &offset, txLengthBytes, "transaction length")
+		if err != nil {
+			return params, err
+		}
+		if offset+int(length) > len(data) {
+			return params, fmt.Errorf("transaction %d: length %d exceeds remaining data", len(params.Transactions), length)
+		}
+		tx := make([]byte, length)
+		copy(tx, data[offset:offset+int(length)])
+		params.Transactions = append(params.Transactions, tx)
+		offset += int(length)
+	}
+
+	return params, nil
+}
+
+func decodeContext(data []byte, offset *int) (Context, error) {
+	var c Context
+
+	numSequenced, err := decodeUint(data, offset, contextCountBytes, "num_sequenced_transactions")
+	if err != nil {
+		return c, err
+	}
+	c.NumSequencedTransactions = numSequenced
+
+	numSubsequent, err := decodeUint(data, offset, contextCountBytes, "num_subsequent_queue_transactions")
+	if err != nil {
+		return c, err
+	}
+	c.NumSubsequentQueueTransactions = numSubsequent
+
+	timestamp, err := decodeUint(data, offset, contextTimeBytes, "timestamp")
+	if err != nil {
+		return c, err
+	}
+	c.Timestamp = timestamp
+
+	blockNumber, err := decodeUint(data, offset, contextTimeBytes, "block_number")
+	if err != nil {
+		return c, err
+	}
+	c.BlockNumber = blockNumber
+
+	return c, nil
+}
+
+func decodeUint(data []byte, offset *int, width int, field string) (uint64, error) {
+	if *offset+width > len(data) {
+		return 0, fmt.Errorf("%s: unexpected end of data", field)
+	}
+	var b [8]byte
+	copy(b[8-width:], data[*offset:*offset+width])
+	*offset += width
+	return binary.BigEndian.Uint64(b[:]), nil
+}
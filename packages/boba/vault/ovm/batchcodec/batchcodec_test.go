@@ -0,0 +1,119 @@
+// Copyright (C) Immutability, LLC - All Rights Reserved
+// Unauthorized copying of this file, via any medium is strictly prohibited
+// Proprietary and confidential
+
+package batchcodec
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeDecodeRoundtrip(t *testing.T) {
+	cases := []struct {
+		name   string
+		params AppendSequencerBatchParams
+	}{
+		{
+			name: "all fields populated",
+			params: AppendSequencerBatchParams{
+				ShouldStartAtElement:  42,
+				TotalElementsToAppend: 3,
+				Contexts: []Context{
+					{NumSequencedTransactions: 2, NumSubsequentQueueTransactions: 1, Timestamp: 1000, BlockNumber: 500},
+				},
+				Transactions: [][]byte{{0x01, 0x02}, {0x03}},
+			},
+		},
+		{
+			// A batch with no contexts and no transactions must round trip
+			// through Encode/Decode without a nil-vs-empty-slice mismatch.
+			name: "zero contexts and transactions",
+			params: AppendSequencerBatchParams{
+				ShouldStartAtElement:  7,
+				TotalElementsToAppend: 0,
+			},
+		},
+		{
+			name: "zero-length transaction",
+			params: AppendSequencerBatchParams{
+				ShouldStartAtElement:  1,
+				TotalElementsToAppend: 1,
+				Contexts: []Context{
+					{NumSequencedTransactions: 0, NumSubsequentQueueTransactions: 1, Timestamp: 1, BlockNumber: 1},
+				},
+				Transactions: [][]byte{{}},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			encoded, err := Encode(c.params)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			decoded, err := Decode(encoded)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			reencoded, err := Encode(decoded)
+			if err != nil {
+				t.Fatalf("re-Encode: %v", err)
+			}
+			if !bytes.Equal(encoded, reencoded) {
+				t.Fatalf("roundtrip mismatch: encoded %x, re-encoded %x", encoded, reencoded)
+			}
+		})
+	}
+}
+
+func TestEncodeOverflow(t *testing.T) {
+	cases := []struct {
+		name   string
+		params AppendSequencerBatchParams
+	}{
+		{
+			name: "should_start_at_element overflows 5 bytes",
+			params: AppendSequencerBatchParams{
+				ShouldStartAtElement: 1 << 40,
+			},
+		},
+		{
+			name: "total_elements_to_append overflows 3 bytes",
+			params: AppendSequencerBatchParams{
+				TotalElementsToAppend: 1 << 24,
+			},
+		},
+		{
+			name: "context field overflows 3 bytes",
+			params: AppendSequencerBatchParams{
+				Contexts: []Context{{NumSequencedTransactions: 1 << 24}},
+			},
+		},
+		{
+			name: "context timestamp overflows 5 bytes",
+			params: AppendSequencerBatchParams{
+				Contexts: []Context{{Timestamp: 1 << 40}},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := Encode(c.params); err == nil {
+				t.Fatal("expected an overflow error, got nil")
+			}
+		})
+	}
+}
+
+func TestDecodeTruncatedData(t *testing.T) {
+	encoded, err := Encode(AppendSequencerBatchParams{ShouldStartAtElement: 1, TotalElementsToAppend: 1})
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if _, err := Decode(encoded[:len(encoded)-1]); err == nil {
+		t.Fatal("expected an error decoding truncated data, got nil")
+	}
+}